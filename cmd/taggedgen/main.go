@@ -0,0 +1,51 @@
+// Command taggedgen generates tagged.Union declarations from a oneof schema.
+//
+//	taggedgen -in schema.tagged -out value_union.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/splizard/tagged/taggedgen"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "taggedgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	in := flag.String("in", "", "path to the oneof schema (required)")
+	out := flag.String("out", "", "output path for the generated Go source (defaults to stdout)")
+	flag.Parse()
+
+	if *in == "" {
+		return fmt.Errorf("-in is required")
+	}
+
+	input, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	schema, err := taggedgen.Parse(input)
+	if err != nil {
+		return err
+	}
+
+	output := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		output = f
+	}
+	return taggedgen.Generate(output, schema)
+}
@@ -53,6 +53,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sync"
 	"unsafe"
 )
 
@@ -112,6 +113,17 @@ func Fields[Buf buffer, Values any, Union isUnion[Buf, Values]](union Union) Val
 	return union.values()
 }
 
+// MustFields is like [Fields] but doesn't need a union value to call it with,
+// so only the union type itself needs to be specified, e.g.
+// tagged.MustFields[Float](). The first call for a given union type does the
+// same reflection work as [Fields]; every call after that, for that type,
+// skips the per-field reflection and returns a copy of the cached accessor.
+// Each call, cached or not, still makes one heap allocation.
+func MustFields[Union fielder[Values], Values any]() Values {
+	var union Union
+	return union.values()
+}
+
 type isUnion[Buf buffer, Values any] interface {
 	~struct {
 		UnionMethods[Buf, Values]
@@ -120,6 +132,13 @@ type isUnion[Buf buffer, Values any] interface {
 	values() Values
 }
 
+// fielder is satisfied by any union type, regardless of its buffer type;
+// it exists so that [MustFields] only needs Union spelled out explicitly,
+// with Values inferred from Union's values method.
+type fielder[Values any] interface {
+	values() Values
+}
+
 // As is used to define a field within a tagged union struct.
 type As[Union any, Value any] struct {
 	Field[Union]
@@ -194,7 +213,11 @@ func (field *As[Union, Value]) get(ptr unsafe.Pointer) any {
 
 func hasPointers(value reflect.Type) bool {
 	switch value.Kind() {
-	case reflect.Ptr, reflect.Chan, reflect.Map, reflect.Interface, reflect.Slice, reflect.Func, reflect.UnsafePointer:
+	// String is listed here alongside the obvious reference kinds because a
+	// string header is itself a data pointer plus a length: copying one into
+	// a raw [N]byte buffer hides that pointer from the garbage collector,
+	// which can reclaim the backing array out from under it.
+	case reflect.Ptr, reflect.Chan, reflect.Map, reflect.Interface, reflect.Slice, reflect.Func, reflect.UnsafePointer, reflect.String:
 		return true
 	case reflect.Struct:
 		for i := 0; i < value.NumField(); i++ {
@@ -234,18 +257,31 @@ type gettable interface {
 	get(unsafe.Pointer) any
 }
 
+// fieldsCache memoizes, per Values type, a fully-loaded prototype Values so
+// that repeated calls to [Fields] or [MustFields] for the same union type
+// only pay for reflection once.
+var fieldsCache sync.Map // map[reflect.Type]any, value is *Values
+
 func (union UnionMethods[Buf, Values]) values() Values {
 	var buffer Buf
 	var values Values
+	typ := reflect.TypeOf(&values).Elem()
+	if cached, ok := fieldsCache.Load(typ); ok {
+		return *cached.(*Values)
+	}
 	var rvalue = reflect.ValueOf(&values).Elem()
-	var direct = reflect.TypeOf(buffer).Kind() == reflect.Array
+	// reflect.TypeOf(buffer) reports the dynamic type of buffer's value,
+	// which is always nil when Buf is any itself; reflect.TypeOf(&buffer)
+	// reports Buf's static type instead, so this also works for Buf=any.
+	var direct = reflect.TypeOf(&buffer).Elem().Kind() == reflect.Array
 	for i := 0; i < rvalue.NumField(); i++ {
 		if i > math.MaxInt32 {
 			panic(fmt.Sprintf("too many fields in %T", values))
 		}
 		rvalue.Field(i).Addr().Interface().(loadable).load(int16(i), direct, unsafe.Sizeof(buffer), unsafe.Offsetof(union.buf))
 	}
-	return values
+	actual, _ := fieldsCache.LoadOrStore(typ, &values)
+	return *actual.(*Values)
 }
 
 // Interface returns the value of the tagged union as an any value.
@@ -253,7 +289,10 @@ func (union UnionMethods[Buf, Values]) Interface() any {
 	var buffer Buf
 	var values Values
 	var rvalue = reflect.ValueOf(&values).Elem()
-	var direct = reflect.TypeOf(buffer).Kind() == reflect.Array
+	// reflect.TypeOf(buffer) reports the dynamic type of buffer's value,
+	// which is always nil when Buf is any itself; reflect.TypeOf(&buffer)
+	// reports Buf's static type instead, so this also works for Buf=any.
+	var direct = reflect.TypeOf(&buffer).Elem().Kind() == reflect.Array
 	i := union.tag
 	getter := rvalue.Field(int(i)).Addr().Interface().(gettable)
 	getter.load(int16(i), direct, unsafe.Sizeof(buffer), unsafe.Offsetof(union.buf))
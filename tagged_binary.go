@@ -0,0 +1,367 @@
+package tagged
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// tagOptions is the parsed form of a `tagged:"..."` struct tag attached to a
+// variant field of a union's Values struct, e.g. `tagged:"little,pad=4"`.
+//
+// Only "big"/"little" (byte order) and "pad=N" (N zero bytes written after
+// the payload on Marshal, and skipped on Unmarshal) are supported. Any other
+// option, "sizeof=" included, is ignored; length prefixes of strings and
+// byte slices are always varints and cannot be configured per field.
+type tagOptions struct {
+	order binary.ByteOrder
+	pad   int
+}
+
+func parseTagOptions(tag reflect.StructTag) tagOptions {
+	opts := tagOptions{order: binary.BigEndian}
+	raw, ok := tag.Lookup("tagged")
+	if !ok {
+		return opts
+	}
+	for _, part := range strings.Split(raw, ",") {
+		switch {
+		case part == "big":
+			opts.order = binary.BigEndian
+		case part == "little":
+			opts.order = binary.LittleEndian
+		case strings.HasPrefix(part, "pad="):
+			n, _ := strconv.Atoi(strings.TrimPrefix(part, "pad="))
+			opts.pad = n
+		}
+	}
+	return opts
+}
+
+// MarshalBinary encodes the union as varint(tag) followed by the payload
+// encoding of whichever variant is currently set, in big-endian byte order.
+// Use [NewCodec] instead if variants need per-field byte order or padding
+// control via the `tagged` struct tag.
+func (union UnionMethods[Buf, Values]) MarshalBinary() ([]byte, error) {
+	tag := union.getTag()
+	var buf bytes.Buffer
+	if err := writeVarint(&buf, uint64(uint16(tag.tag))); err != nil {
+		return nil, err
+	}
+	if err := encodeValue(&buf, binary.BigEndian, reflect.ValueOf(union.Interface())); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a union previously encoded with [UnionMethods.MarshalBinary].
+func (union *UnionMethods[Buf, Values]) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	var buffer Buf
+	var values Values
+	rvalue := reflect.ValueOf(&values).Elem()
+	tagv, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+	tag := int16(tagv)
+	if int(tag) < 0 || int(tag) >= rvalue.NumField() {
+		return fmt.Errorf("tagged: tag %d out of range for %T", tag, values)
+	}
+	direct := reflect.TypeOf(&buffer).Elem().Kind() == reflect.Array
+	field := rvalue.Field(int(tag)).Addr().Interface()
+	field.(loadable).load(tag, direct, unsafe.Sizeof(buffer), unsafe.Offsetof(union.buf))
+	decoder, ok := field.(binaryDecoder)
+	if !ok {
+		return fmt.Errorf("tagged: %T does not support binary decoding", values)
+	}
+	return decoder.decodeUnionBinary(binary.BigEndian, r, unsafe.Pointer(union))
+}
+
+// Codec is a reusable binary codec for a tagged union type. It caches the
+// parsed `tagged` struct tag options for every variant so that repeated
+// Marshal/Unmarshal calls don't re-parse struct tags on each use.
+//
+// A variant's struct tag controls its wire encoding, e.g.
+// `tagged:"little,pad=4"` encodes that variant little-endian and follows its
+// payload with 4 zero bytes, which Unmarshal then skips. An absent tag
+// defaults to big-endian with no padding.
+//
+// Build one with [NewCodec] and keep it alongside the union's Fields accessor:
+//
+//	var FloatWith = tagged.Fields(Float{})
+//	var FloatCodec = tagged.NewCodec(Float{})
+type Codec[Buf buffer, Values any, Union isUnion[Buf, Values]] struct {
+	opts []tagOptions
+}
+
+// NewCodec builds a [Codec] for a union type by reading the `tagged` struct
+// tag on every field of its Values struct.
+func NewCodec[Buf buffer, Values any, Union isUnion[Buf, Values]](union Union) *Codec[Buf, Values, Union] {
+	var values Values
+	rt := reflect.TypeOf(values)
+	codec := &Codec[Buf, Values, Union]{opts: make([]tagOptions, rt.NumField())}
+	for i := 0; i < rt.NumField(); i++ {
+		codec.opts[i] = parseTagOptions(rt.Field(i).Tag)
+	}
+	return codec
+}
+
+func (c *Codec[Buf, Values, Union]) optionsFor(tag int16) tagOptions {
+	if int(tag) >= 0 && int(tag) < len(c.opts) {
+		return c.opts[tag]
+	}
+	return tagOptions{order: binary.BigEndian}
+}
+
+// Marshal encodes union as varint(tag) || payload, using the byte order and
+// padding configured on the matching variant's struct tag.
+func (c *Codec[Buf, Values, Union]) Marshal(union Union) ([]byte, error) {
+	raw := *(*UnionMethods[Buf, Values])(unsafe.Pointer(&union))
+	tag := raw.getTag()
+	opts := c.optionsFor(tag.tag)
+	var buf bytes.Buffer
+	if err := writeVarint(&buf, uint64(uint16(tag.tag))); err != nil {
+		return nil, err
+	}
+	if err := encodeValue(&buf, opts.order, reflect.ValueOf(raw.Interface())); err != nil {
+		return nil, err
+	}
+	for i := 0; i < opts.pad; i++ {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes data into union, using the byte order configured on the
+// matching variant's struct tag.
+func (c *Codec[Buf, Values, Union]) Unmarshal(data []byte, union *Union) error {
+	r := bytes.NewReader(data)
+	var buffer Buf
+	var values Values
+	rvalue := reflect.ValueOf(&values).Elem()
+	tagv, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+	tag := int16(tagv)
+	if int(tag) < 0 || int(tag) >= rvalue.NumField() {
+		return fmt.Errorf("tagged: tag %d out of range for %T", tag, values)
+	}
+	opts := c.optionsFor(tag)
+	direct := reflect.TypeOf(&buffer).Elem().Kind() == reflect.Array
+	raw := (*UnionMethods[Buf, Values])(unsafe.Pointer(union))
+	field := rvalue.Field(int(tag)).Addr().Interface()
+	field.(loadable).load(tag, direct, unsafe.Sizeof(buffer), unsafe.Offsetof(raw.buf))
+	decoder, ok := field.(binaryDecoder)
+	if !ok {
+		return fmt.Errorf("tagged: %T does not support binary decoding", values)
+	}
+	if err := decoder.decodeUnionBinary(opts.order, r, unsafe.Pointer(union)); err != nil {
+		return err
+	}
+	if opts.pad > 0 {
+		if _, err := io.CopyN(io.Discard, r, int64(opts.pad)); err != nil {
+			return fmt.Errorf("tagged: reading %d pad bytes: %w", opts.pad, err)
+		}
+	}
+	return nil
+}
+
+// Marshal encodes a union value as varint(tag) || payload, in big-endian
+// byte order. Build a [Codec] with [NewCodec] for reuse across many calls or
+// for per-variant byte order and padding control.
+func Marshal[Buf buffer, Values any, Union isUnion[Buf, Values]](union Union) ([]byte, error) {
+	raw := *(*UnionMethods[Buf, Values])(unsafe.Pointer(&union))
+	return raw.MarshalBinary()
+}
+
+// Unmarshal decodes data, previously produced by [Marshal], into union.
+func Unmarshal[Buf buffer, Values any, Union isUnion[Buf, Values]](data []byte, union *Union) error {
+	raw := (*UnionMethods[Buf, Values])(unsafe.Pointer(union))
+	return raw.UnmarshalBinary(data)
+}
+
+// binaryDecoder is implemented by every As[Union, Value] field and lets
+// [UnionMethods.UnmarshalBinary] and [Codec.Unmarshal] decode a variant's
+// payload and write the resulting union value into dst without knowing
+// Value statically.
+type binaryDecoder interface {
+	loadable
+	decodeUnionBinary(order binary.ByteOrder, r *bytes.Reader, dst unsafe.Pointer) error
+}
+
+func (field *As[Union, Value]) decodeUnionBinary(order binary.ByteOrder, r *bytes.Reader, dst unsafe.Pointer) error {
+	var zero Value
+	decoded, err := decodeValue(r, order, reflect.TypeOf(zero))
+	if err != nil {
+		return err
+	}
+	result := field.New(decoded.Interface().(Value))
+	*(*Union)(dst) = result
+	return nil
+}
+
+// encodeValue writes v's payload encoding: fixed-width for sized numerics
+// and bool, varint-length-prefixed bytes for strings and byte slices, a
+// varint element count followed by each element for other slices, and
+// field-by-field recursion for structs - unless the struct implements
+// [encoding.BinaryMarshaler] (as every tagged union does), in which case its
+// own length-prefixed encoding is used, enabling nested unions.
+//
+// Plain int/uint are deliberately not supported: their width isn't fixed
+// across platforms, so [binary.Write] rejects them outright. Use an
+// explicitly sized integer type (int32, int64, ...) in a union variant
+// instead.
+func encodeValue(w io.Writer, order binary.ByteOrder, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		var b byte
+		if v.Bool() {
+			b = 1
+		}
+		_, err := w.Write([]byte{b})
+		return err
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return binary.Write(w, order, v.Interface())
+	case reflect.String:
+		return writeBytes(w, []byte(v.String()))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return writeBytes(w, v.Bytes())
+		}
+		if err := writeVarint(w, uint64(v.Len())); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := encodeValue(w, order, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		if m, ok := ptr.Interface().(encoding.BinaryMarshaler); ok {
+			data, err := m.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			return writeBytes(w, data)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if err := encodeValue(w, order, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("tagged: cannot marshal value of kind %s", v.Kind())
+	}
+}
+
+// decodeValue is the inverse of encodeValue for the static type typ.
+func decodeValue(r *bytes.Reader, order binary.ByteOrder, typ reflect.Type) (reflect.Value, error) {
+	switch typ.Kind() {
+	case reflect.Bool:
+		b, err := r.ReadByte()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b != 0), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		ptr := reflect.New(typ)
+		if err := binary.Read(r, order, ptr.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return ptr.Elem(), nil
+	case reflect.String:
+		data, err := readBytes(r)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(string(data)).Convert(typ), nil
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			data, err := readBytes(r)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v := reflect.MakeSlice(typ, len(data), len(data))
+			reflect.Copy(v, reflect.ValueOf(data))
+			return v, nil
+		}
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.MakeSlice(typ, int(n), int(n))
+		for i := 0; i < int(n); i++ {
+			elem, err := decodeValue(r, order, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Index(i).Set(elem)
+		}
+		return v, nil
+	case reflect.Struct:
+		ptr := reflect.New(typ)
+		if u, ok := ptr.Interface().(encoding.BinaryUnmarshaler); ok {
+			data, err := readBytes(r)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if err := u.UnmarshalBinary(data); err != nil {
+				return reflect.Value{}, err
+			}
+			return ptr.Elem(), nil
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			fv, err := decodeValue(r, order, typ.Field(i).Type)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			ptr.Elem().Field(i).Set(fv)
+		}
+		return ptr.Elem(), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("tagged: cannot unmarshal value of kind %s", typ.Kind())
+	}
+}
+
+func writeVarint(w io.Writer, v uint64) error {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(scratch[:], v)
+	_, err := w.Write(scratch[:n])
+	return err
+}
+
+func writeBytes(w io.Writer, b []byte) error {
+	if err := writeVarint(w, uint64(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
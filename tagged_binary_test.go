@@ -0,0 +1,77 @@
+package tagged_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/splizard/tagged"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	want := FloatWith.Bits64.New(math.Pi)
+	data, err := tagged.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Float
+	if err := tagged.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if FloatWith.Bits64.Get(got) != math.Pi {
+		t.Fatalf("got %v, want %v", FloatWith.Bits64.Get(got), math.Pi)
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	codec := tagged.NewCodec(Float{})
+	want := FloatWith.Bits32.New(1.5)
+	data, err := codec.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Float
+	if err := codec.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if FloatWith.Bits32.Get(got) != 1.5 {
+		t.Fatalf("got %v, want %v", FloatWith.Bits32.Get(got), 1.5)
+	}
+}
+
+type Padded tagged.Union[[4]byte, struct {
+	Count tagged.As[Padded, int32] `tagged:"big,pad=4"`
+}]
+
+var PaddedWith = tagged.Fields(Padded{})
+
+func TestCodecPad(t *testing.T) {
+	codec := tagged.NewCodec(Padded{})
+	want := PaddedWith.Count.New(7)
+	data, err := codec.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1+4+4 { // varint(tag) + int32 payload + 4 pad bytes
+		t.Fatalf("got %d bytes, want %d", len(data), 1+4+4)
+	}
+	var got Padded
+	if err := codec.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if PaddedWith.Count.Get(got) != 7 {
+		t.Fatalf("got %v, want 7", PaddedWith.Count.Get(got))
+	}
+}
+
+type Unsized tagged.Union[[8]byte, struct {
+	N tagged.As[Unsized, int]
+}]
+
+var UnsizedWith = tagged.Fields(Unsized{})
+
+func TestMarshalUnsizedInt(t *testing.T) {
+	_, err := tagged.Marshal(UnsizedWith.N.New(42))
+	if err == nil {
+		t.Fatal("expected an error marshaling a plain int field, got nil")
+	}
+}
@@ -0,0 +1,24 @@
+package tagged_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/splizard/tagged"
+)
+
+func TestMustFields(t *testing.T) {
+	with := tagged.MustFields[Float]()
+	pi := with.Bits64.New(math.Pi)
+	if with.Bits64.Get(pi) != math.Pi {
+		t.Fatalf("got %v, want %v", with.Bits64.Get(pi), math.Pi)
+	}
+}
+
+func TestFieldsAgreeAcrossCalls(t *testing.T) {
+	a := tagged.Fields(Float{})
+	b := tagged.Fields(Float{})
+	if a.Bits32.Field != b.Bits32.Field {
+		t.Fatalf("expected repeated Fields() calls to agree on field identity")
+	}
+}
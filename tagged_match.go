@@ -0,0 +1,93 @@
+package tagged
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// Match dispatches a union value to the handler function in handlers whose
+// field name matches the union's currently set variant, replacing the
+// boilerplate of:
+//
+//	switch tagged.FieldOf(value) {
+//	case FloatWith.Bits32.Field:
+//		...
+//	case FloatWith.Bits64.Field:
+//		...
+//	}
+//
+// with:
+//
+//	tagged.Match(value, struct {
+//		Bits32 func(float32)
+//		Bits64 func(float64)
+//	}{
+//		Bits32: func(f float32) { ... },
+//		Bits64: func(f float64) { ... },
+//	})
+//
+// Handlers may include a `Default func(any)` field, called with the current
+// variant's value when no field of handlers matches it (including when the
+// matching field is nil). The dispatch table mapping variant tag to handler
+// field is built once per (Union, Handlers) type pair and cached, so repeat
+// calls skip rebuilding that table - but each call still boxes the current
+// variant's value and invokes its handler through reflect.Value.Call, which
+// costs more than a plain switch statement or direct function call.
+func Match[Buf buffer, Values any, Union isUnion[Buf, Values], Handlers any](union Union, handlers Handlers) {
+	table := matchTableFor[Buf, Values, Union, Handlers]()
+
+	raw := *(*UnionMethods[Buf, Values])(unsafe.Pointer(&union))
+	tag := raw.getTag()
+	rhandlers := reflect.ValueOf(handlers)
+
+	if int(tag.tag) >= 0 && int(tag.tag) < len(table.fields) {
+		if idx := table.fields[tag.tag]; idx >= 0 {
+			fn := rhandlers.Field(idx)
+			if !fn.IsNil() {
+				fn.Call([]reflect.Value{reflect.ValueOf(raw.Interface())})
+				return
+			}
+		}
+	}
+	if table.defaultField >= 0 {
+		fn := rhandlers.Field(table.defaultField)
+		if !fn.IsNil() {
+			fn.Call([]reflect.Value{reflect.ValueOf(raw.Interface())})
+		}
+	}
+}
+
+// matchTable maps a union's variant tag to the index, within a Handlers
+// struct, of the field that handles it; -1 means "no matching field".
+type matchTable struct {
+	fields       []int
+	defaultField int
+}
+
+var matchTableCache sync.Map // map[[2]reflect.Type]*matchTable
+
+func matchTableFor[Buf buffer, Values any, Union isUnion[Buf, Values], Handlers any]() *matchTable {
+	var values Values
+	var handlers Handlers
+	key := [2]reflect.Type{reflect.TypeOf(values), reflect.TypeOf(handlers)}
+	if cached, ok := matchTableCache.Load(key); ok {
+		return cached.(*matchTable)
+	}
+
+	vt := reflect.TypeOf(values)
+	ht := reflect.TypeOf(handlers)
+	table := &matchTable{fields: make([]int, vt.NumField()), defaultField: -1}
+	for i := range table.fields {
+		table.fields[i] = -1
+		if field, ok := ht.FieldByName(vt.Field(i).Name); ok && len(field.Index) == 1 {
+			table.fields[i] = field.Index[0]
+		}
+	}
+	if field, ok := ht.FieldByName("Default"); ok && len(field.Index) == 1 {
+		table.defaultField = field.Index[0]
+	}
+
+	actual, _ := matchTableCache.LoadOrStore(key, table)
+	return actual.(*matchTable)
+}
@@ -0,0 +1,37 @@
+package tagged_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/splizard/tagged"
+)
+
+func TestMatch(t *testing.T) {
+	var got float64
+	pi := FloatWith.Bits64.New(math.Pi)
+	tagged.Match(pi, struct {
+		Bits32 func(float32)
+		Bits64 func(float64)
+	}{
+		Bits32: func(f float32) { t.Fatalf("unexpected Bits32 handler call") },
+		Bits64: func(f float64) { got = f },
+	})
+	if got != math.Pi {
+		t.Fatalf("got %v, want %v", got, math.Pi)
+	}
+}
+
+func TestMatchDefault(t *testing.T) {
+	var got any
+	pi := FloatWith.Bits32.New(1.5)
+	tagged.Match(pi, struct {
+		Bits64  func(float64)
+		Default func(any)
+	}{
+		Default: func(v any) { got = v },
+	})
+	if got != float32(1.5) {
+		t.Fatalf("got %v, want %v", got, float32(1.5))
+	}
+}
@@ -0,0 +1,142 @@
+package tagged
+
+// Protobuf wire types, as assigned by the protobuf encoding spec. The
+// Append/Read helpers below use these; generated oneof glue (see taggedgen)
+// calls those helpers to produce and consume bytes that interoperate on the
+// wire with a hand-written protobuf message sharing the same field numbers.
+const (
+	ProtoVarint  = 0
+	ProtoFixed64 = 1
+	ProtoBytes   = 2
+	ProtoFixed32 = 5
+)
+
+// AppendProtoTag appends a protobuf field key, the varint combining a field
+// number and wire type that precedes every field's value on the wire.
+func AppendProtoTag(buf []byte, fieldNumber, wireType int) []byte {
+	return AppendProtoVarint(buf, 0, uint64(fieldNumber)<<3|uint64(wireType))
+}
+
+// AppendProtoVarint appends fieldNumber's tag followed by v encoded as a
+// protobuf varint. Pass fieldNumber as 0 to append only the varint, with no
+// preceding tag (used internally by the other Append functions).
+func AppendProtoVarint(buf []byte, fieldNumber int, v uint64) []byte {
+	if fieldNumber > 0 {
+		buf = AppendProtoTag(buf, fieldNumber, ProtoVarint)
+	}
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+// AppendProtoZigzag32 appends fieldNumber's tag followed by v, zigzag
+// encoded the way protobuf's sint32 fields are.
+func AppendProtoZigzag32(buf []byte, fieldNumber int, v int32) []byte {
+	return AppendProtoVarint(buf, fieldNumber, uint64(uint32(v<<1)^uint32(v>>31)))
+}
+
+// AppendProtoZigzag64 appends fieldNumber's tag followed by v, zigzag
+// encoded the way protobuf's sint64 fields are.
+func AppendProtoZigzag64(buf []byte, fieldNumber int, v int64) []byte {
+	return AppendProtoVarint(buf, fieldNumber, uint64(v<<1)^uint64(v>>63))
+}
+
+// AppendProtoFixed32 appends fieldNumber's tag followed by v as 4
+// little-endian bytes, the way protobuf's fixed32/sfixed32/float fields are.
+func AppendProtoFixed32(buf []byte, fieldNumber int, v uint32) []byte {
+	buf = AppendProtoTag(buf, fieldNumber, ProtoFixed32)
+	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// AppendProtoFixed64 appends fieldNumber's tag followed by v as 8
+// little-endian bytes, the way protobuf's fixed64/sfixed64/double fields
+// are.
+func AppendProtoFixed64(buf []byte, fieldNumber int, v uint64) []byte {
+	buf = AppendProtoTag(buf, fieldNumber, ProtoFixed64)
+	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24), byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+}
+
+// AppendProtoBytes appends fieldNumber's tag followed by v's length as a
+// varint and then v itself, the way protobuf's string/bytes fields are.
+func AppendProtoBytes(buf []byte, fieldNumber int, v []byte) []byte {
+	buf = AppendProtoTag(buf, fieldNumber, ProtoBytes)
+	buf = AppendProtoVarint(buf, 0, uint64(len(v)))
+	return append(buf, v...)
+}
+
+// ReadProtoTag reads a protobuf field key from the front of data, returning
+// the field number, wire type, and the number of bytes consumed.
+func ReadProtoTag(data []byte) (fieldNumber, wireType int, n int) {
+	v, n := ReadProtoVarint(data)
+	return int(v >> 3), int(v & 7), n
+}
+
+// ReadProtoVarint reads a protobuf varint from the front of data, returning
+// its value and the number of bytes consumed, or (0, 0) if data doesn't
+// contain a complete varint.
+func ReadProtoVarint(data []byte) (uint64, int) {
+	var v uint64
+	var shift uint
+	for i, b := range data {
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v, i + 1
+		}
+		shift += 7
+	}
+	return 0, 0
+}
+
+// ReadProtoZigzag32 reads a zigzag-encoded protobuf varint, the way
+// protobuf's sint32 fields are encoded, returning its value and the number
+// of bytes consumed.
+func ReadProtoZigzag32(data []byte) (int32, int) {
+	v, n := ReadProtoVarint(data)
+	u := uint32(v)
+	return int32(u>>1) ^ -int32(u&1), n
+}
+
+// ReadProtoZigzag64 reads a zigzag-encoded protobuf varint, the way
+// protobuf's sint64 fields are encoded, returning its value and the number
+// of bytes consumed.
+func ReadProtoZigzag64(data []byte) (int64, int) {
+	v, n := ReadProtoVarint(data)
+	return int64(v>>1) ^ -int64(v&1), n
+}
+
+// ReadProtoFixed32 reads 4 little-endian bytes from the front of data, the
+// way protobuf's fixed32/sfixed32/float fields are encoded, returning the
+// value and the number of bytes consumed, or (0, 0) if data is too short.
+func ReadProtoFixed32(data []byte) (uint32, int) {
+	if len(data) < 4 {
+		return 0, 0
+	}
+	v := uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24
+	return v, 4
+}
+
+// ReadProtoFixed64 reads 8 little-endian bytes from the front of data, the
+// way protobuf's fixed64/sfixed64/double fields are encoded, returning the
+// value and the number of bytes consumed, or (0, 0) if data is too short.
+func ReadProtoFixed64(data []byte) (uint64, int) {
+	if len(data) < 8 {
+		return 0, 0
+	}
+	v := uint64(data[0]) | uint64(data[1])<<8 | uint64(data[2])<<16 | uint64(data[3])<<24 |
+		uint64(data[4])<<32 | uint64(data[5])<<40 | uint64(data[6])<<48 | uint64(data[7])<<56
+	return v, 8
+}
+
+// ReadProtoBytes reads a varint length followed by that many bytes from the
+// front of data, the way protobuf's string/bytes fields are encoded,
+// returning the content and the number of bytes consumed, or (nil, 0) if
+// data doesn't contain a complete, in-bounds length-delimited value.
+func ReadProtoBytes(data []byte) ([]byte, int) {
+	length, n := ReadProtoVarint(data)
+	if n == 0 || uint64(n)+length > uint64(len(data)) {
+		return nil, 0
+	}
+	return data[n : uint64(n)+length], n + int(length)
+}
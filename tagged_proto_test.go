@@ -0,0 +1,78 @@
+package tagged_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/splizard/tagged"
+)
+
+func TestProtoVarintRoundTrip(t *testing.T) {
+	for _, want := range []uint64{0, 1, 127, 128, 300, math.MaxUint64} {
+		buf := tagged.AppendProtoVarint(nil, 7, want)
+		field, wire, n := tagged.ReadProtoTag(buf)
+		if field != 7 || wire != tagged.ProtoVarint {
+			t.Fatalf("got field=%d wire=%d, want field=7 wire=%d", field, wire, tagged.ProtoVarint)
+		}
+		got, m := tagged.ReadProtoVarint(buf[n:])
+		if got != want || n+m != len(buf) {
+			t.Fatalf("got %d (consumed %d), want %d (consumed %d)", got, n+m, want, len(buf))
+		}
+	}
+}
+
+func TestProtoZigzagRoundTrip(t *testing.T) {
+	for _, want := range []int32{0, 1, -1, math.MaxInt32, math.MinInt32} {
+		buf := tagged.AppendProtoZigzag32(nil, 1, want)
+		field, _, n := tagged.ReadProtoTag(buf)
+		if field != 1 {
+			t.Fatalf("got field %d, want 1", field)
+		}
+		got, _ := tagged.ReadProtoZigzag32(buf[n:])
+		if got != want {
+			t.Fatalf("got %d, want %d", got, want)
+		}
+	}
+	for _, want := range []int64{0, 1, -1, math.MaxInt64, math.MinInt64} {
+		buf := tagged.AppendProtoZigzag64(nil, 1, want)
+		_, _, n := tagged.ReadProtoTag(buf)
+		got, _ := tagged.ReadProtoZigzag64(buf[n:])
+		if got != want {
+			t.Fatalf("got %d, want %d", got, want)
+		}
+	}
+}
+
+func TestProtoFixedRoundTrip(t *testing.T) {
+	buf := tagged.AppendProtoFixed32(nil, 2, math.Float32bits(math.Pi))
+	_, wire, n := tagged.ReadProtoTag(buf)
+	if wire != tagged.ProtoFixed32 {
+		t.Fatalf("got wire type %d, want %d", wire, tagged.ProtoFixed32)
+	}
+	got, _ := tagged.ReadProtoFixed32(buf[n:])
+	if math.Float32frombits(got) != float32(math.Pi) {
+		t.Fatalf("got %v, want %v", math.Float32frombits(got), float32(math.Pi))
+	}
+
+	buf = tagged.AppendProtoFixed64(nil, 3, math.Float64bits(math.Pi))
+	_, wire, n = tagged.ReadProtoTag(buf)
+	if wire != tagged.ProtoFixed64 {
+		t.Fatalf("got wire type %d, want %d", wire, tagged.ProtoFixed64)
+	}
+	got64, _ := tagged.ReadProtoFixed64(buf[n:])
+	if math.Float64frombits(got64) != math.Pi {
+		t.Fatalf("got %v, want %v", math.Float64frombits(got64), math.Pi)
+	}
+}
+
+func TestProtoBytesRoundTrip(t *testing.T) {
+	buf := tagged.AppendProtoBytes(nil, 4, []byte("hello"))
+	_, wire, n := tagged.ReadProtoTag(buf)
+	if wire != tagged.ProtoBytes {
+		t.Fatalf("got wire type %d, want %d", wire, tagged.ProtoBytes)
+	}
+	got, m := tagged.ReadProtoBytes(buf[n:])
+	if string(got) != "hello" || n+m != len(buf) {
+		t.Fatalf("got %q (consumed %d), want %q (consumed %d)", got, n+m, "hello", len(buf))
+	}
+}
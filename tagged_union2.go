@@ -0,0 +1,227 @@
+package tagged
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// Union2 is like [Union] but lets each variant independently choose between
+// storing its value inline in Buf or spilling to a heap pointer boxed in an
+// interface field, based on the variant's size and whether it contains
+// pointers. [Union] forces every variant through an interface as soon as a
+// single variant needs [any], even if most variants would easily fit in a
+// small fixed buffer; Union2 keeps those small variants allocation-free and
+// only pays the interface/heap cost for the ones that actually need it.
+// The layout decision for every variant is made once, inside [Fields2].
+//
+// For example:
+//
+//	type Value tagged.Union2[[8]byte, struct {
+//		Small tagged.As2[Value, float64]
+//		Large tagged.As2[Value, [256]byte]
+//	}]
+//
+//	var ValueWith = tagged.Fields2(Value{})
+//
+// Small.New copies its float64 directly into the 8-byte buffer; Large.New,
+// whose variant doesn't fit, spills to a heap-allocated [256]byte instead of
+// forcing Small through the same treatment. Use [Stats] to inspect the
+// layout decisions Fields2 made for a given Buf choice.
+type Union2[Buf buffer, Values any] struct {
+	UnionMethods2[Buf, Values]
+}
+
+// Field2 identifies a particular field within a specified Union2 type.
+type Field2[Union any] struct {
+	_        *[0]Union
+	tag      int16
+	set      uintptr
+	boxSet   uintptr
+	spillSet uintptr
+	spill    bool
+}
+
+// FieldOf2 returns the currently tagged field in the given Union2 value.
+func FieldOf2[Buf buffer, Values any, Union isUnion2[Buf, Values]](union Union) Field2[Union] {
+	tag := union.getTag()
+	return Field2[Union]{
+		tag:      tag.tag,
+		set:      tag.set,
+		boxSet:   tag.boxSet,
+		spillSet: tag.spillSet,
+		spill:    tag.spill,
+	}
+}
+
+// Fields2 returns an accessor value that can be used to create and access
+// the fields of a Union2 value. The spill decision for every variant is
+// made here, once, based on its size and whether it contains pointers.
+func Fields2[Buf buffer, Values any, Union isUnion2[Buf, Values]](union Union) Values {
+	return union.values()
+}
+
+type isUnion2[Buf buffer, Values any] interface {
+	~struct {
+		UnionMethods2[Buf, Values]
+	}
+	getTag() Field2[struct{}]
+	values() Values
+}
+
+// As2 is used to define a field within a Union2 struct.
+type As2[Union any, Value any] struct {
+	Field2[Union]
+}
+
+// New returns a new value of the Union2 type, with the specified field set
+// to the given value. If the field's variant doesn't fit in Buf, or
+// contains pointers, value is spilled to a heap allocation instead.
+func (f As2[Union, Value]) New(value Value) Union {
+	if f.set == 0 {
+		panic("tagged.Field must be initialized before use")
+	}
+	var union Union
+	ptr := unsafe.Pointer(&union)
+	*(*int16)(ptr) = f.tag
+	if f.spill {
+		*(*bool)(unsafe.Add(ptr, f.spillSet)) = true
+		*(*any)(unsafe.Add(ptr, f.boxSet)) = &value
+		return union
+	}
+	*(*Value)(unsafe.Add(ptr, f.set)) = value
+	return union
+}
+
+// Get returns the value of the specified field in the given Union2 value.
+// If the field is not set, Get will panic.
+func (f As2[Union, Value]) Get(union Union) Value {
+	value, ok := f.Lookup(union)
+	if !ok {
+		panic("tagged.Field.Get called with wrong tag")
+	}
+	return value
+}
+
+// Lookup returns the value of the specified field in the given Union2
+// value. If the field is not set, Lookup will return the zero value for the
+// field type and false.
+func (f As2[Union, Value]) Lookup(union Union) (Value, bool) {
+	if f.set == 0 {
+		panic("tagged.Field must be initialized before use")
+	}
+	var zero Value
+	ptr := unsafe.Pointer(&union)
+	if *(*int16)(ptr) != f.tag {
+		return zero, false
+	}
+	if f.spill {
+		boxed := *(*any)(unsafe.Add(ptr, f.boxSet))
+		return *boxed.(*Value), true
+	}
+	return *(*Value)(unsafe.Add(ptr, f.set)), true
+}
+
+func (field *As2[Union, Value]) load2(tag int16, direct bool, bufSize, setOffset, boxOffset, spillOffset uintptr) {
+	var value Value
+	field.tag = tag
+	field.set = setOffset
+	field.boxSet = boxOffset
+	field.spillSet = spillOffset
+	// A non-array Buf (i.e. any) can't be blindly overwritten with a
+	// variant's raw bytes the way a [N]byte array can: the GC scans that
+	// field as a two-word interface, and stomping it with non-pointer bytes
+	// corrupts the heap. So every variant spills unless Buf is an array.
+	field.spill = !direct || hasPointers(reflect.TypeOf(value)) || unsafe.Sizeof(value) > bufSize
+}
+
+func (As2[Union, Value]) valueType() reflect.Type {
+	var value Value
+	return reflect.TypeOf(value)
+}
+
+type loadable2 interface {
+	load2(tag int16, direct bool, bufSize, setOffset, boxOffset, spillOffset uintptr)
+}
+
+// variantType is implemented by every As2[Union, Value] field and reports
+// Value's static type without needing a loaded field, for use by [Stats].
+type variantType interface {
+	valueType() reflect.Type
+}
+
+// UnionMethods2 are the exported methods for a Union2 type. Included and
+// exported for documentation purposes only.
+//
+// box precedes buf so that every instantiation of UnionMethods2 shares an
+// identical layout up to buf, regardless of Buf's size: spilled variants
+// only ever touch the fixed tag/spill/box prefix.
+type UnionMethods2[Buf any, Values any] struct {
+	tag   int16
+	spill bool
+	box   any
+	buf   Buf
+}
+
+func (union UnionMethods2[Buf, Values]) getTag() Field2[struct{}] {
+	return Field2[struct{}]{
+		tag:      union.tag,
+		set:      unsafe.Offsetof(union.buf),
+		boxSet:   unsafe.Offsetof(union.box),
+		spillSet: unsafe.Offsetof(union.spill),
+		spill:    union.spill,
+	}
+}
+
+func (union UnionMethods2[Buf, Values]) values() Values {
+	var buffer Buf
+	var values Values
+	// reflect.TypeOf(buffer) would report the dynamic type of buffer's
+	// value, which is always nil when Buf is any itself; reflect.TypeOf(&buffer)
+	// reports Buf's static type instead, so this also works for Buf=any.
+	var direct = reflect.TypeOf(&buffer).Elem().Kind() == reflect.Array
+	var rvalue = reflect.ValueOf(&values).Elem()
+	for i := 0; i < rvalue.NumField(); i++ {
+		if i > math.MaxInt32 {
+			panic(fmt.Sprintf("too many fields in %T", values))
+		}
+		rvalue.Field(i).Addr().Interface().(loadable2).load2(int16(i), direct, unsafe.Sizeof(buffer), unsafe.Offsetof(union.buf), unsafe.Offsetof(union.box), unsafe.Offsetof(union.spill))
+	}
+	return values
+}
+
+// VariantStats describes the layout [Fields2] computed for a single variant
+// of a Union2's Values struct.
+type VariantStats struct {
+	Name        string
+	Size        uintptr
+	Align       uintptr
+	Spill       bool
+	HasPointers bool
+}
+
+// Stats reports per-variant size, alignment, spill status and
+// pointer-containing-ness for a Union2 type, so callers can pick a Buf size
+// without trial and error.
+func Stats[Buf buffer, Values any, Union isUnion2[Buf, Values]](union Union) []VariantStats {
+	var buffer Buf
+	var values Values
+	bufSize := unsafe.Sizeof(buffer)
+	direct := reflect.TypeOf(&buffer).Elem().Kind() == reflect.Array
+	rtype := reflect.TypeOf(values)
+	rvalue := reflect.ValueOf(&values).Elem()
+	stats := make([]VariantStats, rtype.NumField())
+	for i := 0; i < rtype.NumField(); i++ {
+		typ := rvalue.Field(i).Addr().Interface().(variantType).valueType()
+		pointers := hasPointers(typ)
+		stats[i] = VariantStats{
+			Name:        rtype.Field(i).Name,
+			Size:        typ.Size(),
+			Align:       uintptr(typ.Align()),
+			HasPointers: pointers,
+			Spill:       !direct || pointers || typ.Size() > bufSize,
+		}
+	}
+	return stats
+}
@@ -0,0 +1,103 @@
+package tagged_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/splizard/tagged"
+)
+
+type Mixed tagged.Union2[[8]byte, struct {
+	Small tagged.As2[Mixed, float64]
+	Large tagged.As2[Mixed, [256]byte]
+}]
+
+var MixedWith = tagged.Fields2(Mixed{})
+
+func TestUnion2Inline(t *testing.T) {
+	v := MixedWith.Small.New(math.Pi)
+	if got := MixedWith.Small.Get(v); got != math.Pi {
+		t.Fatalf("got %v, want %v", got, math.Pi)
+	}
+	if _, ok := MixedWith.Large.Lookup(v); ok {
+		t.Fatalf("expected Large to not be set")
+	}
+}
+
+func TestUnion2Spill(t *testing.T) {
+	var want [256]byte
+	want[0], want[255] = 1, 2
+	v := MixedWith.Large.New(want)
+	if got := MixedWith.Large.Get(v); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+type WithString tagged.Union2[[32]byte, struct {
+	Text  tagged.As2[WithString, string]
+	Bytes tagged.As2[WithString, []byte]
+}]
+
+var WithStringWith = tagged.Fields2(WithString{})
+
+// TestUnion2SpillsPointerVariants checks that string/slice variants always
+// spill to the heap, even though a string or slice header (16/24 bytes)
+// comfortably fits in a [32]byte buffer - storing one inline would hide its
+// data pointer from the garbage collector.
+func TestUnion2SpillsPointerVariants(t *testing.T) {
+	stats := tagged.Stats(WithString{})
+	for _, s := range stats {
+		if !s.Spill {
+			t.Fatalf("expected %s to spill despite fitting in the buffer, got %+v", s.Name, s)
+		}
+		if !s.HasPointers {
+			t.Fatalf("expected %s to be reported as containing pointers, got %+v", s.Name, s)
+		}
+	}
+
+	v := WithStringWith.Text.New("hello")
+	if got := WithStringWith.Text.Get(v); got != "hello" {
+		t.Fatalf("got %q, want %q", got, "hello")
+	}
+
+	v = WithStringWith.Bytes.New([]byte("world"))
+	if got := WithStringWith.Bytes.Get(v); string(got) != "world" {
+		t.Fatalf("got %q, want %q", got, "world")
+	}
+}
+
+type AnyBuf tagged.Union2[any, struct {
+	Small tagged.As2[AnyBuf, int64]
+}]
+
+var AnyBufWith = tagged.Fields2(AnyBuf{})
+
+// TestUnion2AnyBufAlwaysSpills checks that a Union2 with Buf=any always
+// spills, even a small pointer-free variant like int64: Buf's static field
+// type is any, so the GC scans it as a two-word interface, and writing raw
+// variant bytes directly over it (as the inline fast path does for an array
+// Buf) would corrupt the heap.
+func TestUnion2AnyBufAlwaysSpills(t *testing.T) {
+	stats := tagged.Stats(AnyBuf{})
+	if !stats[0].Spill {
+		t.Fatalf("expected an any Buf to force spill, got %+v", stats[0])
+	}
+
+	v := AnyBufWith.Small.New(42)
+	if got := AnyBufWith.Small.Get(v); got != 42 {
+		t.Fatalf("got %v, want 42", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	stats := tagged.Stats(Mixed{})
+	if stats[0].Name != "Small" || stats[0].Spill {
+		t.Fatalf("expected Small to not spill, got %+v", stats[0])
+	}
+	if stats[1].Name != "Large" || !stats[1].Spill {
+		t.Fatalf("expected Large to spill, got %+v", stats[1])
+	}
+	if stats[1].Size != 256 {
+		t.Fatalf("got size %d, want 256", stats[1].Size)
+	}
+}
@@ -0,0 +1,94 @@
+package taggedgen_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/splizard/tagged/taggedgen"
+)
+
+const schema = `
+package values
+
+oneof Value {
+	int32 int_value = 1;
+	string str_value = 2;
+	double float_value = 3;
+}
+`
+
+const scalarSchema = `
+package values
+
+oneof Value {
+	int32 int_value = 1;
+	double float_value = 2;
+}
+`
+
+func generate(t *testing.T, schema string) string {
+	t.Helper()
+	parsed, err := taggedgen.Parse(strings.NewReader(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := taggedgen.Generate(&buf, parsed); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// TestGenerate checks that a oneof mixing scalars with a string field picks
+// an any buffer: a string header contains a pointer that a fixed-size
+// [N]byte buffer would hide from the garbage collector.
+func TestGenerate(t *testing.T) {
+	out := generate(t, schema)
+	for _, want := range []string{
+		"package values",
+		"type Value tagged.Union[any, struct {",
+		"IntValue",
+		"tagged.As[Value, int32]",
+		"`protobuf:\"1\"`",
+		"var ValueWith = tagged.Fields(Value{})",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("generated source missing %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "[16]byte") {
+		t.Fatalf("expected a string field to force an any buffer, got:\n%s", out)
+	}
+}
+
+// TestGenerateScalarsOnly checks that a oneof of only fixed-width scalars
+// still picks the smallest fixed-size array buffer.
+func TestGenerateScalarsOnly(t *testing.T) {
+	out := generate(t, scalarSchema)
+	if !strings.Contains(out, "type Value tagged.Union[[8]byte, struct {") {
+		t.Fatalf("expected an [8]byte buffer, got:\n%s", out)
+	}
+}
+
+// TestGenerateMarshalBinary checks that Generate emits a MarshalBinary and
+// UnmarshalBinary pair that encode each variant as protobuf wire bytes
+// keyed by its oneof field number, not tagged.Codec's own field-index
+// scheme, so a generated union interoperates on the wire with a
+// hand-written protobuf message.
+func TestGenerateMarshalBinary(t *testing.T) {
+	out := generate(t, schema)
+	for _, want := range []string{
+		"func (v Value) MarshalBinary() ([]byte, error)",
+		"func (v *Value) UnmarshalBinary(data []byte) error",
+		"case ValueWith.IntValue.Field:",
+		"tagged.AppendProtoVarint(nil, 1,",
+		"tagged.AppendProtoBytes(nil, 2,",
+		"tagged.AppendProtoFixed64(nil, 3,",
+		"tagged.ReadProtoTag(data)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("generated source missing %q, got:\n%s", want, out)
+		}
+	}
+}